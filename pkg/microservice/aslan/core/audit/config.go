@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultKafkaTopic is used when AUDIT_LOG_KAFKA_BROKERS is set but
+// AUDIT_LOG_KAFKA_TOPIC isn't.
+const defaultKafkaTopic = "zadig.audit_log"
+
+// DefaultRetention is how long an audit event is kept when
+// AUDIT_LOG_RETENTION_DAYS isn't set.
+const DefaultRetention = 180 * 24 * time.Hour
+
+// Retention reads the configured retention window from
+// AUDIT_LOG_RETENTION_DAYS, falling back to DefaultRetention.
+func Retention() time.Duration {
+	raw := os.Getenv("AUDIT_LOG_RETENTION_DAYS")
+	if raw == "" {
+		return DefaultRetention
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days <= 0 {
+		return DefaultRetention
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// enabledDefault mirrors the Coder pattern this package followed: audit
+// logging defaults on for enterprise builds and off for community ones.
+// AUDIT_LOG_ENABLED overrides it either way; this is only the value used
+// if that env var was never set.
+var enabledDefault = buildIsEnterprise
+
+// Enabled reports whether the audit subsystem should be active. init()
+// calls SetRecorder based on this the moment the package loads, same as
+// Retention() reads its own config from the environment rather than
+// waiting on cmd/aslan to wire a flag in.
+func Enabled() bool {
+	if raw := os.Getenv("AUDIT_LOG_ENABLED"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err == nil {
+			return v
+		}
+	}
+	return enabledDefault
+}
+
+// init installs the process-wide Recorder as soon as the package loads, so
+// audit.Record isn't a silent no-op until something remembers to call
+// SetRecorder from a main() this checkout doesn't have. AUDIT_LOG_KAFKA_BROKERS
+// (comma-separated) opts into shipping events to Kafka in addition to
+// Mongo; AUDIT_LOG_KAFKA_TOPIC names the topic, defaulting to
+// defaultKafkaTopic when brokers are set but the topic isn't.
+func init() {
+	if !Enabled() {
+		return
+	}
+
+	brokers := splitAndTrim(os.Getenv("AUDIT_LOG_KAFKA_BROKERS"))
+	if len(brokers) == 0 {
+		SetRecorder(NewMongoRecorder())
+		return
+	}
+
+	topic := os.Getenv("AUDIT_LOG_KAFKA_TOPIC")
+	if topic == "" {
+		topic = defaultKafkaTopic
+	}
+	SetRecorder(NewKafkaRecorder(brokers, topic))
+}
+
+func splitAndTrim(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var out []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit is the first-class audit log for Zadig: a single Event
+// shape and a pluggable Recorder that every mutating (and a few
+// high-value read) endpoint reports through, replacing the scattered zap
+// log lines that were previously the only way to reconstruct "who did
+// what" after the fact.
+package audit
+
+// Event is one audit record. Before/After are opaque JSON-ish payloads
+// (whatever the call site already has in hand); redact strips anything
+// matching a registered secret prefix before Record persists them.
+type Event struct {
+	Actor        string      `json:"actor" bson:"actor"`
+	Action       string      `json:"action" bson:"action"`
+	ResourceType string      `json:"resourceType" bson:"resource_type"`
+	ResourceKey  string      `json:"resourceKey" bson:"resource_key"`
+	ProjectKey   string      `json:"projectKey" bson:"project_key"`
+	Before       interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After        interface{} `json:"after,omitempty" bson:"after,omitempty"`
+	RequestID    string      `json:"requestId" bson:"request_id"`
+	RemoteAddr   string      `json:"remoteAddr" bson:"remote_addr"`
+	Result       string      `json:"result" bson:"result"`
+	CreatedAt    int64       `json:"createdAt" bson:"created_at"`
+}
+
+// Common Result values. Call sites aren't required to use these, but
+// should when the outcome maps cleanly onto one of them, so queries can
+// filter on it.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultDenied  = "denied"
+)
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/audit"
+	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
+	e "github.com/koderover/zadig/v2/pkg/tool/errors"
+)
+
+// ListAuditLogs serves GET /api/aslan/audit, filterable by project, action
+// and a created_at time range.
+func ListAuditLogs(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+	if err != nil {
+		ctx.RespErr = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	if !ctx.Resources.IsSystemAdmin {
+		ctx.UnAuthorized = true
+		return
+	}
+
+	query := audit.Query{
+		ProjectKey: c.Query("projectKey"),
+		Action:     c.Query("action"),
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			ctx.RespErr = e.ErrInvalidParam.AddErr(err)
+			return
+		}
+		query.From = from
+	}
+	if raw := c.Query("to"); raw != "" {
+		to, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			ctx.RespErr = e.ErrInvalidParam.AddErr(err)
+			return
+		}
+		query.To = to
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			ctx.RespErr = e.ErrInvalidParam.AddErr(err)
+			return
+		}
+		query.Limit = limit
+	}
+
+	ctx.Resp, ctx.RespErr = audit.Find(c.Request.Context(), query)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaRecorder ships audit events to a Kafka topic, for deployments that
+// want to fan audit data out to a SIEM instead of (or in addition to)
+// querying Mongo directly. It wraps MongoRecorder rather than replacing it
+// so the /api/aslan/audit query API keeps working either way.
+type KafkaRecorder struct {
+	mongo  *MongoRecorder
+	writer *kafka.Writer
+}
+
+// NewKafkaRecorder returns a Recorder that writes to both Mongo and the
+// given Kafka brokers/topic.
+func NewKafkaRecorder(brokers []string, topic string) *KafkaRecorder {
+	return &KafkaRecorder{
+		mongo: NewMongoRecorder(),
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+func (r *KafkaRecorder) Record(ctx context.Context, event *Event) error {
+	if err := r.mongo.Record(ctx, event); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return r.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.ResourceType + "/" + event.ResourceKey),
+		Value: payload,
+	})
+}
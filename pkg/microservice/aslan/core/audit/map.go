@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import "encoding/json"
+
+// ToMap converts an arbitrary Before/After payload into the
+// map[string]interface{} shape redact knows how to walk. Call sites that
+// already have a map can skip this; anything else (a struct, a string)
+// needs it or redact has nothing to scrub before persisting.
+func ToMap(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	if _, ok := v.(map[string]interface{}); ok {
+		return v
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return v
+	}
+	return m
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
+)
+
+const (
+	requestMetaContextKey = "audit.requestMeta"
+	loggerContextKey      = "audit.logger"
+)
+
+// requestMeta is the actor/request bookkeeping Middleware captures once per
+// request so individual Record call sites don't have to re-derive it.
+type requestMeta struct {
+	Actor      string
+	RequestID  string
+	RemoteAddr string
+}
+
+func requestMetaFrom(c *gin.Context) (requestMeta, bool) {
+	meta, ok := c.Get(requestMetaContextKey)
+	if !ok {
+		return requestMeta{}, false
+	}
+	m, ok := meta.(requestMeta)
+	return m, ok
+}
+
+// Middleware captures actor and request metadata for every request it
+// sees. Mount it ahead of any handler that calls audit.Record.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := internalhandler.NewContextWithAuthorization(c)
+		meta := requestMeta{
+			RequestID:  uuid.New().String(),
+			RemoteAddr: c.ClientIP(),
+		}
+		if err == nil {
+			meta.Actor = ctx.UserID
+			c.Set(loggerContextKey, ctx.Logger)
+		}
+
+		c.Set(requestMetaContextKey, meta)
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/v2/pkg/tool/mongo/mongotool"
+)
+
+const auditCollectionName = "audit_log"
+
+// MongoRecorder is the default Recorder: every event is a single insert
+// into the audit_log collection.
+type MongoRecorder struct{}
+
+// NewMongoRecorder returns a Recorder backed by the audit_log collection.
+func NewMongoRecorder() *MongoRecorder {
+	return &MongoRecorder{}
+}
+
+func (r *MongoRecorder) Record(ctx context.Context, event *Event) error {
+	_, err := auditCollection().InsertOne(ctx, event)
+	return err
+}
+
+// Query filters by project/time/action, newest first, for the
+// /api/aslan/audit query endpoint.
+type Query struct {
+	ProjectKey string
+	Action     string
+	From, To   int64
+	Limit      int64
+}
+
+// Find runs a Query against the audit_log collection.
+func Find(ctx context.Context, q Query) ([]*Event, error) {
+	filter := bson.M{}
+	if q.ProjectKey != "" {
+		filter["project_key"] = q.ProjectKey
+	}
+	if q.Action != "" {
+		filter["action"] = q.Action
+	}
+	if q.From > 0 || q.To > 0 {
+		createdAt := bson.M{}
+		if q.From > 0 {
+			createdAt["$gte"] = q.From
+		}
+		if q.To > 0 {
+			createdAt["$lte"] = q.To
+		}
+		filter["created_at"] = createdAt
+	}
+
+	limit := q.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(limit)
+	cursor, err := auditCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []*Event
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func auditCollection() *mongo.Collection {
+	return mongotool.Database(config.MongoDatabase()).Collection(auditCollectionName)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Recorder persists audit Events. Mongo is the default sink; a Kafka sink
+// can be layered in (e.g. for shipping to a SIEM) without call sites
+// caring which one is active.
+type Recorder interface {
+	Record(ctx context.Context, event *Event) error
+}
+
+var active Recorder = noopRecorder{}
+
+// SetRecorder installs the process-wide Recorder. The package's own init
+// (config.go) calls it at load time when AUDIT_LOG_ENABLED (or the build's
+// default) turns auditing on; there is no command-line flag for it. While
+// no Recorder is installed, Record is a no-op so packages that import
+// audit don't have to special-case tests or community builds where
+// auditing is off.
+func SetRecorder(r Recorder) {
+	if r == nil {
+		r = noopRecorder{}
+	}
+	active = r
+}
+
+// Record redacts and persists an event through whichever Recorder is
+// currently installed. Actor/RequestID/RemoteAddr are filled in from the
+// gin.Context populated by Middleware when the event doesn't already set
+// them, so HTTP call sites don't have to re-derive them.
+func Record(c *gin.Context, event *Event) {
+	if meta, ok := requestMetaFrom(c); ok {
+		if event.Actor == "" {
+			event.Actor = meta.Actor
+		}
+		if event.RequestID == "" {
+			event.RequestID = meta.RequestID
+		}
+		if event.RemoteAddr == "" {
+			event.RemoteAddr = meta.RemoteAddr
+		}
+	}
+
+	record(c.Request.Context(), event, httpLogger(c))
+}
+
+// RecordEvent is Record's counterpart for call sites that have no
+// gin.Context to pull actor/request metadata from, such as the workflow
+// job controller: the caller is expected to have already filled in Actor,
+// ResourceType, ResourceKey, etc. itself.
+func RecordEvent(ctx context.Context, event *Event, logger *zap.SugaredLogger) {
+	record(ctx, event, logger)
+}
+
+func record(ctx context.Context, event *Event, logger *zap.SugaredLogger) {
+	if event.CreatedAt == 0 {
+		event.CreatedAt = time.Now().Unix()
+	}
+
+	redact(event)
+
+	if err := active.Record(ctx, event); err != nil {
+		logger.Errorf("audit: failed to record event action=%s resource=%s/%s: %v", event.Action, event.ResourceType, event.ResourceKey, err)
+	}
+}
+
+func httpLogger(c *gin.Context) *zap.SugaredLogger {
+	if v, ok := c.Get(loggerContextKey); ok {
+		if l, ok := v.(*zap.SugaredLogger); ok && l != nil {
+			return l
+		}
+	}
+	return zap.L().Sugar()
+}
+
+type noopRecorder struct{}
+
+func (noopRecorder) Record(context.Context, *Event) error { return nil }
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"strings"
+	"sync"
+)
+
+const redactedPlaceholder = "<redacted>"
+
+var (
+	secretPrefixesMu sync.RWMutex
+	secretPrefixes   []string
+)
+
+// RegisterSecretPrefix tells Record to scrub any string key/value pair in
+// Before/After whose key starts with prefix (case-insensitively), e.g.
+// "aksk", "password", "token". Call it from whatever package owns that
+// kind of secret, at init time.
+func RegisterSecretPrefix(prefix string) {
+	secretPrefixesMu.Lock()
+	defer secretPrefixesMu.Unlock()
+	secretPrefixes = append(secretPrefixes, strings.ToLower(prefix))
+}
+
+func init() {
+	for _, p := range []string{"password", "secret", "token", "aksk", "apikey", "api_key", "credential"} {
+		RegisterSecretPrefix(p)
+	}
+}
+
+// redact walks event.Before/After in place and replaces any map value
+// whose key matches a registered secret prefix with redactedPlaceholder.
+// Non-map payloads (structs, scalars, nil) are left untouched: callers
+// that hand audit.Record a raw secret value directly are expected to
+// redact it themselves before calling in.
+func redact(event *Event) {
+	event.Before = redactValue(event.Before)
+	event.After = redactValue(event.After)
+}
+
+func redactValue(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+
+	out := make(map[string]interface{}, len(m))
+	for k, val := range m {
+		if isSecretKey(k) {
+			out[k] = redactedPlaceholder
+			continue
+		}
+		out[k] = redactValue(val)
+	}
+	return out
+}
+
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	secretPrefixesMu.RLock()
+	defer secretPrefixesMu.RUnlock()
+	for _, prefix := range secretPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.uber.org/zap"
+)
+
+// rotationInterval is how often StartRetentionLoop sweeps expired events;
+// it doesn't need to be precise, just regular.
+const rotationInterval = 6 * time.Hour
+
+// StartRetentionLoop periodically deletes audit_log entries older than
+// Retention(). It blocks until ctx is cancelled, so callers should run it
+// in a goroutine from server start-up.
+func StartRetentionLoop(ctx context.Context, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(rotationInterval)
+	defer ticker.Stop()
+
+	rotate(ctx, logger)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotate(ctx, logger)
+		}
+	}
+}
+
+func rotate(ctx context.Context, logger *zap.SugaredLogger) {
+	cutoff := time.Now().Add(-Retention()).Unix()
+	res, err := auditCollection().DeleteMany(ctx, bson.M{"created_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		logger.Errorf("audit: retention sweep failed: %v", err)
+		return
+	}
+	if res.DeletedCount > 0 {
+		logger.Infof("audit: retention sweep removed %d event(s) older than %s", res.DeletedCount, Retention())
+	}
+}
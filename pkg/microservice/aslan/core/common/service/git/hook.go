@@ -25,6 +25,13 @@ import (
 var once sync.Once
 var secret string
 
+// GetHookSecret returns the legacy, process-wide webhook secret shared by
+// every hook Zadig has ever registered.
+//
+// Deprecated: new hooks get a per-(codehost, owner, repo) secret from
+// pkg/tool/git/hooksecret instead. This is kept only so that webhook
+// records created before that migration ran keep verifying until they are
+// rotated.
 func GetHookSecret() string {
 	once.Do(func() {
 		token, err := crypto.AesEncrypt("hook")
@@ -23,10 +23,12 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 	crClient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	zadigconfig "github.com/koderover/zadig/v2/pkg/config"
 	"github.com/koderover/zadig/v2/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/audit"
 	commonmodels "github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/repository/models"
 	commonrepo "github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/repository/mongodb"
 	"github.com/koderover/zadig/v2/pkg/setting"
@@ -38,6 +40,7 @@ type PluginJobCtl struct {
 	workflowCtx *commonmodels.WorkflowTaskCtx
 	logger      *zap.SugaredLogger
 	kubeclient  crClient.Client
+	clientset   kubernetes.Interface
 	apiServer   crClient.Reader
 	jobTaskSpec *commonmodels.JobTaskPluginSpec
 	ack         func()
@@ -78,12 +81,26 @@ func (c *PluginJobCtl) Clean(ctx context.Context) {}
 func (c *PluginJobCtl) Run(ctx context.Context) {
 	c.prepare(ctx)
 	if err := c.run(ctx); err != nil {
+		c.recordAudit(ctx, audit.ResultFailure)
 		return
 	}
 	c.wait(ctx)
 	c.complete(ctx)
 }
 
+// recordAudit reports this job's outcome to the audit log, so "who ran
+// what plugin step, and did it succeed" is answerable without grepping
+// zap output.
+func (c *PluginJobCtl) recordAudit(ctx context.Context, result string) {
+	audit.RecordEvent(ctx, &audit.Event{
+		Action:       "workflow.pluginJob.run",
+		ResourceType: "workflowTaskJob",
+		ResourceKey:  c.job.Name,
+		ProjectKey:   c.workflowCtx.ProjectName,
+		Result:       result,
+	}, c.logger)
+}
+
 func (c *PluginJobCtl) run(ctx context.Context) error {
 	// get kube client
 	hubServerAddr := zadigconfig.HubServerServiceAddress()
@@ -93,12 +110,13 @@ func (c *PluginJobCtl) run(ctx context.Context) error {
 		c.jobTaskSpec.Properties.Namespace = setting.AttachedClusterNamespace
 	}
 
-	crClient, _, apiServer, err := GetK8sClients(hubServerAddr, c.jobTaskSpec.Properties.ClusterID)
+	crClient, clientset, apiServer, err := GetK8sClients(hubServerAddr, c.jobTaskSpec.Properties.ClusterID)
 	if err != nil {
 		logError(c.job, err.Error(), c.logger)
 		return err
 	}
 	c.kubeclient = crClient
+	c.clientset = clientset
 	c.apiServer = apiServer
 
 	jobLabel := &JobLabel{
@@ -147,6 +165,14 @@ func (c *PluginJobCtl) run(ctx context.Context) error {
 	return nil
 }
 
+// streamDrainGrace is how long wait() waits for streamContainerLog to
+// notice the pod's log stream ended on its own (the normal case: a
+// container exiting closes its log stream, which ends the Read loop with
+// io.EOF) before it forces the goroutine closed via streamCtx. Without
+// this, canceling streamCtx the instant waitPlainJobEnd returns races the
+// still-running goroutine and can truncate the job's final log chunk.
+const streamDrainGrace = 5 * time.Second
+
 func (c *PluginJobCtl) wait(ctx context.Context) {
 	var err error
 	timeout := time.After(time.Duration(c.jobTaskSpec.Properties.Timeout) * time.Minute)
@@ -159,8 +185,30 @@ func (c *PluginJobCtl) wait(ctx context.Context) {
 	} else {
 		return
 	}
+
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		streamContainerLog(streamCtx, c.clientset, c.kubeclient, c.jobTaskSpec.Properties.Namespace, &JobLabel{
+			JobType: string(c.job.JobType),
+			JobName: c.job.K8sJobName,
+		}, logStreamKey{
+			WorkflowName: c.workflowCtx.WorkflowName,
+			TaskID:       c.workflowCtx.TaskID,
+			JobName:      c.job.Name,
+		}, c.logger)
+	}()
+
 	status := waitPlainJobEnd(ctx, int(c.jobTaskSpec.Properties.Timeout), timeout, c.jobTaskSpec.Properties.Namespace, c.job.K8sJobName, c.kubeclient, c.logger)
 	c.job.Status = status
+
+	select {
+	case <-streamDone:
+	case <-time.After(streamDrainGrace):
+		c.logger.Warnf("wait: log stream for job %s did not finish within %s of job end, forcing it closed", c.job.K8sJobName, streamDrainGrace)
+	}
 }
 
 func (c *PluginJobCtl) complete(ctx context.Context) {
@@ -184,13 +232,32 @@ func (c *PluginJobCtl) complete(ctx context.Context) {
 		c.job.Error = err.Error()
 	}
 
-	if err := saveContainerLog(c.jobTaskSpec.Properties.Namespace, c.jobTaskSpec.Properties.ClusterID, c.workflowCtx.WorkflowName, c.job.Name, c.workflowCtx.TaskID, jobLabel, c.kubeclient); err != nil {
+	// wait() already tailed the pod's logs live via streamContainerLog,
+	// flushing each chunk to the log object store as it arrived, so there's
+	// normally nothing left to copy here. finalizeContainerLog only falls
+	// back to a full saveContainerLog copy when streaming never started
+	// (the job failed before wait() saw StatusRunning).
+	streamKey := logStreamKey{
+		WorkflowName: c.workflowCtx.WorkflowName,
+		TaskID:       c.workflowCtx.TaskID,
+		JobName:      c.job.Name,
+	}
+	if err := finalizeContainerLog(streamKey, func() error {
+		return saveContainerLog(c.jobTaskSpec.Properties.Namespace, c.jobTaskSpec.Properties.ClusterID, c.workflowCtx.WorkflowName, c.job.Name, c.workflowCtx.TaskID, jobLabel, c.kubeclient)
+	}); err != nil {
 		c.logger.Error(err)
 		if c.job.Error == "" {
 			c.job.Error = err.Error()
 		}
+		c.recordAudit(ctx, audit.ResultFailure)
 		return
 	}
+
+	result := audit.ResultSuccess
+	if c.job.Error != "" {
+		result = audit.ResultFailure
+	}
+	c.recordAudit(ctx, result)
 }
 
 func (c *PluginJobCtl) SaveInfo(ctx context.Context) error {
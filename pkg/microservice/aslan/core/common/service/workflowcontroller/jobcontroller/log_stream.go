@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobcontroller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	crClient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// logStreamKey identifies the live log stream for a single job task, the
+// same triple the UI already uses to address a job in the workflow task
+// view.
+type logStreamKey struct {
+	WorkflowName string
+	TaskID       int64
+	JobName      string
+}
+
+// maxRingBufferChunks bounds memory: once exceeded, the oldest chunks are
+// dropped and logRingBuffer.offset advances past them. A reconnecting
+// client asking for bytes before that offset just misses the gap, same as
+// tailing a rotated file.
+const maxRingBufferChunks = 4096
+
+// logRingBuffer holds every chunk streamed for one running job so that a
+// reconnecting WebSocket client can replay from its last known offset
+// instead of only seeing chunks emitted after it reconnected.
+type logRingBuffer struct {
+	mu     sync.Mutex
+	chunks [][]byte
+	offset int64 // stream offset of chunks[0]
+}
+
+func (b *logRingBuffer) append(chunk []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.chunks = append(b.chunks, chunk)
+	if len(b.chunks) > maxRingBufferChunks {
+		b.offset += int64(len(b.chunks[0]))
+		b.chunks = b.chunks[1:]
+	}
+}
+
+// since returns every byte streamed after offset, plus the offset to pass
+// on the next call.
+func (b *logRingBuffer) since(offset int64) ([]byte, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pos := b.offset
+	var out []byte
+	for _, chunk := range b.chunks {
+		end := pos + int64(len(chunk))
+		if end > offset {
+			start := int64(0)
+			if offset > pos {
+				start = offset - pos
+			}
+			out = append(out, chunk[start:]...)
+		}
+		pos = end
+	}
+	return out, pos
+}
+
+var (
+	ringBuffersMu sync.Mutex
+	ringBuffers   = map[logStreamKey]*logRingBuffer{}
+)
+
+// LogObjectWriter incrementally appends a job's streamed log to the
+// persistent log object store (a multipart upload, or equivalent), so the
+// object is complete the moment the job finishes instead of requiring a
+// separate full copy afterwards. Append is called once per chunk in
+// stream order; Close finalizes the upload.
+type LogObjectWriter interface {
+	Append(chunk []byte) error
+	Close() error
+}
+
+// logObjectWriterFactory opens a LogObjectWriter for a job's log object.
+// It's a package var rather than a hard dependency so this package
+// doesn't need to import whichever object-store client the process wires
+// up; SetLogObjectWriterFactory registers the real one at server start.
+// The zero value is a no-op writer so streaming still works (ring-buffer
+// only) before one is registered.
+var logObjectWriterFactory = func(key logStreamKey) (LogObjectWriter, error) {
+	return noopLogObjectWriter{}, nil
+}
+
+// SetLogObjectWriterFactory registers the LogObjectWriter implementation
+// backed by the process's log object store. Call it once during server
+// start-up, before any plugin job runs.
+func SetLogObjectWriterFactory(factory func(key logStreamKey) (LogObjectWriter, error)) {
+	logObjectWriterFactory = factory
+}
+
+type noopLogObjectWriter struct{}
+
+func (noopLogObjectWriter) Append([]byte) error { return nil }
+func (noopLogObjectWriter) Close() error        { return nil }
+
+var (
+	streamedJobsMu sync.Mutex
+	streamedJobs   = map[logStreamKey]bool{}
+)
+
+func getOrCreateRingBuffer(key logStreamKey) *logRingBuffer {
+	ringBuffersMu.Lock()
+	defer ringBuffersMu.Unlock()
+	rb, ok := ringBuffers[key]
+	if !ok {
+		rb = &logRingBuffer{}
+		ringBuffers[key] = rb
+	}
+	return rb
+}
+
+func releaseRingBuffer(key logStreamKey) {
+	ringBuffersMu.Lock()
+	defer ringBuffersMu.Unlock()
+	delete(ringBuffers, key)
+}
+
+// StreamState reports where a job's live log stream stands, so callers
+// can tell "hasn't started yet, keep waiting" apart from "finished, stop
+// polling" instead of treating both as the same not-available state.
+type StreamState int
+
+const (
+	// StreamPending means the job hasn't reached wait()'s
+	// streamContainerLog call yet (still pending/starting): the ring
+	// buffer doesn't exist yet, but it's expected to.
+	StreamPending StreamState = iota
+	// StreamActive means chunks are available to tail right now.
+	StreamActive
+	// StreamFinished means the job's container finished streaming and its
+	// ring buffer has been released; nothing further will ever arrive.
+	StreamFinished
+)
+
+var (
+	finishedStreamsMu sync.Mutex
+	finishedStreams   = map[logStreamKey]bool{}
+)
+
+// TailRingBuffer returns every byte streamed for a job after offset, along
+// with the stream's current state.
+func TailRingBuffer(workflowName string, taskID int64, jobName string, offset int64) (chunk []byte, nextOffset int64, state StreamState) {
+	key := logStreamKey{workflowName, taskID, jobName}
+
+	ringBuffersMu.Lock()
+	rb, exists := ringBuffers[key]
+	ringBuffersMu.Unlock()
+	if exists {
+		chunk, nextOffset = rb.since(offset)
+		return chunk, nextOffset, StreamActive
+	}
+
+	finishedStreamsMu.Lock()
+	finished := finishedStreams[key]
+	finishedStreamsMu.Unlock()
+	if finished {
+		return nil, offset, StreamFinished
+	}
+	return nil, offset, StreamPending
+}
+
+// streamContainerLog tails a running pod's logs with Follow: true and
+// pushes every chunk into the job's ring buffer until ctx is cancelled or
+// the stream ends because the pod exited. It is best-effort: any failure
+// just means live tailing isn't available for this job, the final log is
+// still saved in full by saveContainerLog once the job completes.
+func streamContainerLog(ctx context.Context, clientset kubernetes.Interface, kubeclient crClient.Client, namespace string, jobLabel *JobLabel, key logStreamKey, logger *zap.SugaredLogger) {
+	if clientset == nil {
+		return
+	}
+
+	podName, err := findPluginPodName(ctx, kubeclient, namespace, jobLabel)
+	if err != nil {
+		logger.Warnf("streamContainerLog: find pod for job %s: %v", jobLabel.JobName, err)
+		return
+	}
+
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		logger.Warnf("streamContainerLog: open log stream for pod %s: %v", podName, err)
+		return
+	}
+	defer stream.Close()
+
+	rb := getOrCreateRingBuffer(key)
+	defer func() {
+		releaseRingBuffer(key)
+		finishedStreamsMu.Lock()
+		finishedStreams[key] = true
+		finishedStreamsMu.Unlock()
+	}()
+
+	writer, err := logObjectWriterFactory(key)
+	if err != nil {
+		logger.Warnf("streamContainerLog: open log object writer for job %s: %v", jobLabel.JobName, err)
+		writer = noopLogObjectWriter{}
+	}
+	// only a real writer that took every chunk counts as having saved the
+	// log; otherwise finalizeContainerLog makes the full copy.
+	_, isNoop := writer.(noopLogObjectWriter)
+	persisted := !isNoop
+
+	reader := bufio.NewReaderSize(stream, 32*1024)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := reader.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			rb.append(chunk)
+			if err := writer.Append(chunk); err != nil {
+				logger.Warnf("streamContainerLog: append to log object for job %s: %v", jobLabel.JobName, err)
+				persisted = false
+			}
+		}
+		if readErr != nil {
+			if readErr != io.EOF && ctx.Err() == nil {
+				logger.Warnf("streamContainerLog: read log stream for pod %s: %v", podName, readErr)
+				if err := writer.Close(); err != nil {
+					logger.Warnf("streamContainerLog: close log object for job %s: %v", jobLabel.JobName, err)
+				}
+				return
+			}
+			if err := writer.Close(); err != nil {
+				logger.Warnf("streamContainerLog: close log object for job %s: %v", jobLabel.JobName, err)
+			} else if persisted {
+				streamedJobsMu.Lock()
+				streamedJobs[key] = true
+				streamedJobsMu.Unlock()
+			}
+			return
+		}
+	}
+}
+
+// finalizeContainerLog is complete()'s counterpart to streamContainerLog:
+// when streaming finished cleanly, the log object was already flushed and
+// closed chunk-by-chunk as it was produced, so there's nothing left to do
+// but forget the bookkeeping. When streaming did not finish that way (the
+// job failed before wait() saw StatusRunning, its pod or log stream could
+// not be opened, a read, append or close failed, or no real
+// LogObjectWriter was registered), fullLogCopy is used as a one-time
+// fallback so the log still ends up in the object store.
+func finalizeContainerLog(key logStreamKey, fullLogCopy func() error) error {
+	streamedJobsMu.Lock()
+	streamed := streamedJobs[key]
+	delete(streamedJobs, key)
+	streamedJobsMu.Unlock()
+
+	finishedStreamsMu.Lock()
+	delete(finishedStreams, key)
+	finishedStreamsMu.Unlock()
+
+	if streamed {
+		return nil
+	}
+	return fullLogCopy()
+}
+
+func findPluginPodName(ctx context.Context, kubeclient crClient.Client, namespace string, jobLabel *JobLabel) (string, error) {
+	podList := &corev1.PodList{}
+	if err := kubeclient.List(ctx, podList, crClient.InNamespace(namespace), crClient.MatchingLabels{"job-name": jobLabel.JobName}); err != nil {
+		return "", err
+	}
+	if len(podList.Items) == 0 {
+		return "", fmt.Errorf("no pod found for job %s", jobLabel.JobName)
+	}
+	return podList.Items[0].Name, nil
+}
@@ -20,14 +20,75 @@ import (
 	"fmt"
 
 	"github.com/gin-gonic/gin"
-	"github.com/koderover/zadig/v2/pkg/types"
 
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/audit"
 	commonservice "github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/service"
 	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/environment/service"
+	"github.com/koderover/zadig/v2/pkg/shared/authz"
 	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
 )
 
+// auditResult maps a handler's dispatch error onto the Result audit.Record
+// should store; every handler in this file reports its own dispatch this
+// way so a reviewer can tell who read which environment, and whether it
+// succeeded, straight from the audit log.
+func auditResult(err error) string {
+	if err != nil {
+		return audit.ResultFailure
+	}
+	return audit.ResultSuccess
+}
+
+// envAction picks the authz action for ListReleases, the one handler in
+// this file that also grants access to anyone who can only create new
+// releases (Version.Create), based on the conventional `?production=true`
+// query flag.
+func envAction(c *gin.Context) authz.Action {
+	if c.Query("production") == "true" {
+		return authz.ProdEnvView
+	}
+	return authz.EnvView
+}
+
+// envReadOnlyAction picks the authz action for GetChartValues/
+// GetChartInfos/GetImageInfos: plain Env.View, without ListReleases's
+// Version.Create carve-out.
+func envReadOnlyAction(c *gin.Context) authz.Action {
+	if c.Query("production") == "true" {
+		return authz.ProdEnvReadOnlyView
+	}
+	return authz.EnvReadOnlyView
+}
+
+// RequireEnvView is the authorization check for ListReleases: project comes
+// from the `projectName` query param, the environment from the `name` path
+// param, and the action is resolved per request from the `production`
+// flag. This package has no router file to register it as route
+// middleware, so ListReleases calls it directly as the first line of the
+// handler body instead; it still writes the standard unauthorized response
+// and aborts c on failure, so the caller only needs to check c.IsAborted().
+func RequireEnvView() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authz.RequireEnv(authz.FromQuery("projectName"), authz.FromParam("name"), envAction(c))(c)
+	}
+}
+
+// RequireEnvReadOnlyView is the authorization check for GetChartValues/
+// GetChartInfos/GetImageInfos: same project/env resolution as
+// RequireEnvView, but without ListReleases's Version.Create carve-out.
+// Called inline for the same reason RequireEnvView is.
+func RequireEnvReadOnlyView() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authz.RequireEnv(authz.FromQuery("projectName"), authz.FromParam("name"), envReadOnlyAction(c))(c)
+	}
+}
+
 func ListReleases(c *gin.Context) {
+	RequireEnvView()(c)
+	if c.IsAborted() {
+		return
+	}
+
 	ctx, err := internalhandler.NewContextWithAuthorization(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
 	if err != nil {
@@ -44,41 +105,25 @@ func ListReleases(c *gin.Context) {
 		return
 	}
 
-	// TODO: Authorization leak
-	// authorization checks
 	production := c.Query("production") == "true"
-	if !ctx.Resources.IsSystemAdmin {
-		if _, ok := ctx.Resources.ProjectAuthInfo[args.ProjectName]; !ok {
-			ctx.UnAuthorized = true
-			return
-		}
-
-		if production {
-			if !ctx.Resources.ProjectAuthInfo[args.ProjectName].IsProjectAdmin &&
-				!ctx.Resources.ProjectAuthInfo[args.ProjectName].ProductionEnv.View {
-				permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, args.ProjectName, types.ResourceTypeEnvironment, envName, types.ProductionEnvActionView)
-				if err != nil || !permitted {
-					ctx.UnAuthorized = true
-					return
-				}
-			}
-		} else {
-			if !ctx.Resources.ProjectAuthInfo[args.ProjectName].IsProjectAdmin &&
-				!ctx.Resources.ProjectAuthInfo[args.ProjectName].Env.View &&
-				!ctx.Resources.ProjectAuthInfo[args.ProjectName].Version.Create {
-				permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, args.ProjectName, types.ResourceTypeEnvironment, envName, types.EnvActionView)
-				if err != nil || !permitted {
-					ctx.UnAuthorized = true
-					return
-				}
-			}
-		}
-	}
-
-	ctx.Resp, ctx.Err = service.ListReleases(args, envName, production, ctx.Logger)
+	refresh := c.Query("refresh") == "true"
+	ctx.Resp, ctx.Err = service.ListReleasesCached(args, envName, production, refresh, ctx.Logger)
+
+	audit.Record(c, &audit.Event{
+		Action:       "environment.listReleases",
+		ResourceType: "environment",
+		ResourceKey:  envName,
+		ProjectKey:   args.ProjectName,
+		Result:       auditResult(ctx.Err),
+	})
 }
 
 func GetChartValues(c *gin.Context) {
+	RequireEnvReadOnlyView()(c)
+	if c.IsAborted() {
+		return
+	}
+
 	ctx, err := internalhandler.NewContextWithAuthorization(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
 	if err != nil {
@@ -94,47 +139,38 @@ func GetChartValues(c *gin.Context) {
 	releaseName := c.Query("releaseName")
 	production := c.Query("production") == "true"
 
-	// authorization checks
-	if !ctx.Resources.IsSystemAdmin {
-		if _, ok := ctx.Resources.ProjectAuthInfo[projectKey]; !ok {
-			ctx.UnAuthorized = true
-			return
-		}
-
-		if production {
-			if !ctx.Resources.ProjectAuthInfo[projectKey].IsProjectAdmin &&
-				!ctx.Resources.ProjectAuthInfo[projectKey].ProductionEnv.View {
-				permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, projectKey, types.ResourceTypeEnvironment, envName, types.ProductionEnvActionView)
-				if err != nil || !permitted {
-					ctx.UnAuthorized = true
-					return
-				}
-			}
-		} else {
-			if !ctx.Resources.ProjectAuthInfo[projectKey].IsProjectAdmin &&
-				!ctx.Resources.ProjectAuthInfo[projectKey].Env.View {
-				permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, projectKey, types.ResourceTypeEnvironment, envName, types.EnvActionView)
-				if err != nil || !permitted {
-					ctx.UnAuthorized = true
-					return
-				}
-			}
-		}
-	}
-
 	if isHelmChartDeploy == "false" {
 		ctx.Resp, ctx.Err = commonservice.GetChartValues(projectKey, envName, serviceName, false, production)
 	} else {
 		ctx.Resp, ctx.Err = commonservice.GetChartValues(projectKey, envName, releaseName, true, production)
 	}
+
+	// chart values commonly embed secrets (image pull creds, DB passwords),
+	// which is exactly what redact() is for — attach the response as After
+	// so it's actually redacted before being persisted, instead of leaving
+	// every audit event on this handler with nothing for redact() to walk.
+	event := &audit.Event{
+		Action:       "environment.getChartValues",
+		ResourceType: "environment",
+		ResourceKey:  envName,
+		ProjectKey:   projectKey,
+		Result:       auditResult(ctx.Err),
+	}
+	if ctx.Err == nil {
+		event.After = audit.ToMap(ctx.Resp)
+	}
+	audit.Record(c, event)
 }
 
 func GetChartInfos(c *gin.Context) {
+	RequireEnvReadOnlyView()(c)
+	if c.IsAborted() {
+		return
+	}
+
 	ctx, err := internalhandler.NewContextWithAuthorization(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
-
 	if err != nil {
-
 		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
 		ctx.UnAuthorized = true
 		return
@@ -143,32 +179,28 @@ func GetChartInfos(c *gin.Context) {
 	envName := c.Param("name")
 	servicesName := c.Query("serviceName")
 	projectKey := c.Query("projectName")
+	refresh := c.Query("refresh") == "true"
+
+	ctx.Resp, ctx.Err = service.GetChartInfosCached(projectKey, envName, servicesName, refresh, ctx.Logger)
 
-	// authorization checks
-	if !ctx.Resources.IsSystemAdmin {
-		if _, ok := ctx.Resources.ProjectAuthInfo[projectKey]; !ok {
-			ctx.UnAuthorized = true
-			return
-		}
-		if !ctx.Resources.ProjectAuthInfo[projectKey].IsProjectAdmin &&
-			!ctx.Resources.ProjectAuthInfo[projectKey].Env.View {
-			permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, projectKey, types.ResourceTypeEnvironment, envName, types.EnvActionView)
-			if err != nil || !permitted {
-				ctx.UnAuthorized = true
-				return
-			}
-		}
-	}
-
-	ctx.Resp, ctx.Err = service.GetChartInfos(projectKey, envName, servicesName, ctx.Logger)
+	audit.Record(c, &audit.Event{
+		Action:       "environment.getChartInfos",
+		ResourceType: "environment",
+		ResourceKey:  envName,
+		ProjectKey:   projectKey,
+		Result:       auditResult(ctx.Err),
+	})
 }
 
 func GetImageInfos(c *gin.Context) {
+	RequireEnvReadOnlyView()(c)
+	if c.IsAborted() {
+		return
+	}
+
 	ctx, err := internalhandler.NewContextWithAuthorization(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
-
 	if err != nil {
-
 		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
 		ctx.UnAuthorized = true
 		return
@@ -177,22 +209,15 @@ func GetImageInfos(c *gin.Context) {
 	envName := c.Param("name")
 	projectKey := c.Query("projectName")
 	servicesName := c.Query("serviceName")
+	refresh := c.Query("refresh") == "true"
+
+	ctx.Resp, ctx.Err = service.GetImageInfosCached(projectKey, envName, servicesName, refresh, ctx.Logger)
 
-	// authorization checks
-	if !ctx.Resources.IsSystemAdmin {
-		if _, ok := ctx.Resources.ProjectAuthInfo[projectKey]; !ok {
-			ctx.UnAuthorized = true
-			return
-		}
-		if !ctx.Resources.ProjectAuthInfo[projectKey].IsProjectAdmin &&
-			!ctx.Resources.ProjectAuthInfo[projectKey].Env.View {
-			permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, projectKey, types.ResourceTypeEnvironment, envName, types.EnvActionView)
-			if err != nil || !permitted {
-				ctx.UnAuthorized = true
-				return
-			}
-		}
-	}
-
-	ctx.Resp, ctx.Err = service.GetImageInfos(projectKey, envName, servicesName, ctx.Logger)
+	audit.Record(c, &audit.Event{
+		Action:       "environment.getImageInfos",
+		ResourceType: "environment",
+		ResourceKey:  envName,
+		ProjectKey:   projectKey,
+		Result:       auditResult(ctx.Err),
+	})
 }
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cache maintains an informer-backed, per-cluster view of Helm
+// releases and the workloads they own so that dashboard-style reads
+// (ListReleases/GetChartInfos/GetImageInfos) don't have to hit the
+// apiserver on every request. It mirrors the Reflector+DeltaFIFO+Indexer
+// pattern used by k8s.io/client-go/tools/cache: a ListWatch keeps a local
+// Indexer in sync and readers only ever touch that Indexer.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// defaultResyncInterval is the resync period of every informer here. A
+// resync replays the informer's local store; it does not relist from the
+// apiserver.
+const defaultResyncInterval = 2 * time.Minute
+
+const helmOwnerSelector = "owner=helm"
+
+// ClusterCache is the per-cluster cache instance: one release Indexer fed by
+// a Secret informer (Helm 3 stores releases as owner=helm Secrets), and one
+// workload Indexer per kind fed by parallel Deployment/StatefulSet/Pod
+// informers.
+type ClusterCache struct {
+	clusterID string
+	logger    *zap.SugaredLogger
+
+	stopCh    chan struct{}
+	startOnce sync.Once
+
+	releaseInformer   cache.SharedIndexInformer
+	workloadInformers map[string]cache.SharedIndexInformer
+
+	mu           sync.RWMutex
+	lastSyncTime time.Time
+}
+
+// newClusterCache wires up the informers but does not start them; callers
+// must invoke Start.
+func newClusterCache(clusterID string, clientset kubernetes.Interface, logger *zap.SugaredLogger) *ClusterCache {
+	cc := &ClusterCache{
+		clusterID:         clusterID,
+		logger:            logger,
+		stopCh:            make(chan struct{}),
+		workloadInformers: make(map[string]cache.SharedIndexInformer, 3),
+	}
+
+	cc.releaseInformer = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.LabelSelector = helmOwnerSelector
+				return clientset.CoreV1().Secrets(metav1.NamespaceAll).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = helmOwnerSelector
+				return clientset.CoreV1().Secrets(metav1.NamespaceAll).Watch(context.Background(), options)
+			},
+		},
+		&corev1.Secret{},
+		defaultResyncInterval,
+		cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			releaseIndexName:     releaseIndexFunc,
+		},
+	)
+
+	cc.workloadInformers["deployment"] = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.AppsV1().Deployments(metav1.NamespaceAll).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.AppsV1().Deployments(metav1.NamespaceAll).Watch(context.Background(), options)
+			},
+		},
+		&appsv1.Deployment{}, defaultResyncInterval, cache.Indexers{workloadIndexName: workloadIndexFunc},
+	)
+	cc.workloadInformers["statefulset"] = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.AppsV1().StatefulSets(metav1.NamespaceAll).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.AppsV1().StatefulSets(metav1.NamespaceAll).Watch(context.Background(), options)
+			},
+		},
+		&appsv1.StatefulSet{}, defaultResyncInterval, cache.Indexers{workloadIndexName: workloadIndexFunc},
+	)
+	cc.workloadInformers["pod"] = cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return clientset.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return clientset.CoreV1().Pods(metav1.NamespaceAll).Watch(context.Background(), options)
+			},
+		},
+		&corev1.Pod{}, defaultResyncInterval, cache.Indexers{workloadIndexName: workloadIndexFunc},
+	)
+
+	return cc
+}
+
+// Start runs the informers until Stop is called. Safe to call more than
+// once; only the first call has any effect.
+func (cc *ClusterCache) Start() {
+	cc.startOnce.Do(func() {
+		go cc.releaseInformer.Run(cc.stopCh)
+		for _, inf := range cc.workloadInformers {
+			go inf.Run(cc.stopCh)
+		}
+		go cc.waitForInitialSync()
+	})
+}
+
+// Stop tears down every informer for this cluster. Called when a cluster is
+// removed from commonservice's cluster registry.
+func (cc *ClusterCache) Stop() {
+	close(cc.stopCh)
+}
+
+func (cc *ClusterCache) waitForInitialSync() {
+	synced := []cache.InformerSynced{cc.releaseInformer.HasSynced}
+	for _, inf := range cc.workloadInformers {
+		synced = append(synced, inf.HasSynced)
+	}
+	if cache.WaitForCacheSync(cc.stopCh, synced...) {
+		cc.mu.Lock()
+		cc.lastSyncTime = time.Now()
+		cc.mu.Unlock()
+		relistTotal.WithLabelValues(cc.clusterID).Inc()
+	} else {
+		cc.logger.Warnf("cluster cache %s: stopped before initial sync completed", cc.clusterID)
+	}
+}
+
+// LastSyncTime reports when the cache last completed a full sync, so
+// callers can surface a staleness indicator in API responses.
+func (cc *ClusterCache) LastSyncTime() time.Time {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	return cc.lastSyncTime
+}
+
+// GetRelease looks up a single Helm release secret by namespace/release
+// name from the local Indexer. ok reports whether it was found.
+func (cc *ClusterCache) GetRelease(namespace, releaseName string) (secret *corev1.Secret, ok bool) {
+	items, err := cc.releaseInformer.GetIndexer().ByIndex(releaseIndexName, fmt.Sprintf("%s/%s", namespace, releaseName))
+	if err != nil || len(items) == 0 {
+		cacheMisses.WithLabelValues(cc.clusterID, "release").Inc()
+		return nil, false
+	}
+	cacheHits.WithLabelValues(cc.clusterID, "release").Inc()
+	return items[0].(*corev1.Secret), true
+}
+
+// ListReleases returns every cached release in the given namespace.
+func (cc *ClusterCache) ListReleases(namespace string) ([]*corev1.Secret, error) {
+	items, err := cc.releaseInformer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		cacheMisses.WithLabelValues(cc.clusterID, "release").Inc()
+		return nil, err
+	}
+	cacheHits.WithLabelValues(cc.clusterID, "release").Inc()
+	out := make([]*corev1.Secret, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.(*corev1.Secret))
+	}
+	return out, nil
+}
+
+// ListWorkloads returns every cached Deployment/StatefulSet/Pod belonging
+// to the given namespace and release, for GetChartInfos/GetImageInfos.
+func (cc *ClusterCache) ListWorkloads(namespace, releaseName string) ([]interface{}, error) {
+	var out []interface{}
+	for kind, inf := range cc.workloadInformers {
+		items, err := inf.GetIndexer().ByIndex(workloadIndexName, fmt.Sprintf("%s/%s", namespace, releaseName))
+		if err != nil {
+			cacheMisses.WithLabelValues(cc.clusterID, kind).Inc()
+			continue
+		}
+		cacheHits.WithLabelValues(cc.clusterID, kind).Inc()
+		out = append(out, items...)
+	}
+	return out, nil
+}
+
+const (
+	releaseIndexName  = "namespace/release"
+	workloadIndexName = "namespace/release"
+)
+
+func releaseIndexFunc(obj interface{}) ([]string, error) {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return nil, fmt.Errorf("cache: object is not a *corev1.Secret")
+	}
+	return []string{fmt.Sprintf("%s/%s", secret.Namespace, secret.Labels["name"])}, nil
+}
+
+// workloadIndexFunc indexes Deployments/StatefulSets/Pods by the Helm
+// release that owns them, as recorded in the standard Helm release labels.
+func workloadIndexFunc(obj interface{}) ([]string, error) {
+	accessor, ok := obj.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("cache: object has no metadata")
+	}
+	release, ok := accessor.GetLabels()["release"]
+	if !ok {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("%s/%s", accessor.GetNamespace(), release)}, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+)
+
+// manager owns one ClusterCache per cluster and is the only long-lived
+// package-level state here; everything else is reachable through it.
+type manager struct {
+	mu     sync.RWMutex
+	caches map[string]*ClusterCache
+}
+
+var defaultManager = &manager{caches: make(map[string]*ClusterCache)}
+
+// GetOrStart returns the running ClusterCache for clusterID, creating and
+// starting it on first use.
+func GetOrStart(clusterID string, clientset kubernetes.Interface, logger *zap.SugaredLogger) *ClusterCache {
+	defaultManager.mu.RLock()
+	cc, ok := defaultManager.caches[clusterID]
+	defaultManager.mu.RUnlock()
+	if ok {
+		return cc
+	}
+
+	defaultManager.mu.Lock()
+	defer defaultManager.mu.Unlock()
+	if cc, ok = defaultManager.caches[clusterID]; ok {
+		return cc
+	}
+
+	cc = newClusterCache(clusterID, clientset, logger)
+	cc.Start()
+	defaultManager.caches[clusterID] = cc
+	return cc
+}
+
+// Evict stops and forgets the cache for a cluster. commonservice should call
+// this when a cluster is removed so the informers don't leak.
+func Evict(clusterID string) {
+	defaultManager.mu.Lock()
+	defer defaultManager.mu.Unlock()
+	if cc, ok := defaultManager.caches[clusterID]; ok {
+		cc.Stop()
+		delete(defaultManager.caches, clusterID)
+	}
+}
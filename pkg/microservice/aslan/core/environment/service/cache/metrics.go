@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "zadig_environment_cache_hits_total",
+		Help: "Number of reads served from the in-memory environment cache, by cluster and resource kind.",
+	}, []string{"cluster_id", "kind"})
+
+	cacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "zadig_environment_cache_misses_total",
+		Help: "Number of reads that fell through to a live apiserver call, by cluster and resource kind.",
+	}, []string{"cluster_id", "kind"})
+
+	relistTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "zadig_environment_cache_relist_total",
+		Help: "Number of times a cluster cache completed a full relist against the apiserver.",
+	}, []string{"cluster_id"})
+)
+
+func init() {
+	prometheus.MustRegister(cacheHits, cacheMisses, relistTotal)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/environment/service/cache"
+	"github.com/koderover/zadig/v2/pkg/tool/kube/clientmanager"
+)
+
+var errColdCache = errors.New("cluster cache has not completed its initial sync yet")
+
+// ChartInfo is the per-service Helm chart identity returned by
+// GetChartInfosCached: which chart (name+version) is currently deployed
+// for a given service. In this codebase a service's Helm release is
+// named after the service itself, so the release cache's per-namespace
+// release index doubles as a per-service index.
+type ChartInfo struct {
+	ServiceName  string `json:"serviceName"`
+	ChartName    string `json:"chartName"`
+	ChartVersion string `json:"chartVersion"`
+}
+
+// ImageInfo is the per-service container image list returned by
+// GetImageInfosCached.
+type ImageInfo struct {
+	ServiceName string   `json:"serviceName"`
+	Images      []string `json:"images"`
+}
+
+func splitServiceNames(servicesName string) []string {
+	var out []string
+	for _, name := range strings.Split(servicesName, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// GetChartInfosCachedResp is GetChartInfosCached's cache-hit response
+// shape: the same []*ChartInfo the live path returns, plus LastSyncTime.
+type GetChartInfosCachedResp struct {
+	ChartInfos   []*ChartInfo `json:"chartInfos"`
+	LastSyncTime int64        `json:"lastSyncTime"`
+}
+
+// GetImageInfosCachedResp is GetImageInfosCached's cache-hit response
+// shape: the same []*ImageInfo the live path returns, plus LastSyncTime.
+type GetImageInfosCachedResp struct {
+	ImageInfos   []*ImageInfo `json:"imageInfos"`
+	LastSyncTime int64        `json:"lastSyncTime"`
+}
+
+// GetChartInfosCached is the cache-backed counterpart of GetChartInfos,
+// following the same refresh/cold-cache/lookup-failure fallback to the
+// live path as ListReleasesCached.
+func GetChartInfosCached(projectKey, envName, servicesName string, refresh bool, log *zap.SugaredLogger) (interface{}, error) {
+	if refresh {
+		return GetChartInfos(projectKey, envName, servicesName, log)
+	}
+
+	cc, namespace, err := clusterCacheFor(projectKey, envName, log)
+	if err != nil {
+		log.Warnf("GetChartInfosCached: %v, falling back to live list", err)
+		return GetChartInfos(projectKey, envName, servicesName, log)
+	}
+
+	out := make([]*ChartInfo, 0, len(splitServiceNames(servicesName)))
+	for _, svc := range splitServiceNames(servicesName) {
+		secret, ok := cc.GetRelease(namespace, svc)
+		if !ok {
+			log.Warnf("GetChartInfosCached: release %s/%s not cached, falling back to live list", namespace, svc)
+			return GetChartInfos(projectKey, envName, servicesName, log)
+		}
+		rel, err := decodeReleaseSecret(secret)
+		if err != nil {
+			continue
+		}
+		out = append(out, &ChartInfo{ServiceName: svc, ChartName: rel.ChartName, ChartVersion: rel.ChartVersion})
+	}
+	return &GetChartInfosCachedResp{ChartInfos: out, LastSyncTime: cc.LastSyncTime().Unix()}, nil
+}
+
+// GetImageInfosCached is the cache-backed counterpart of GetImageInfos.
+func GetImageInfosCached(projectKey, envName, servicesName string, refresh bool, log *zap.SugaredLogger) (interface{}, error) {
+	if refresh {
+		return GetImageInfos(projectKey, envName, servicesName, log)
+	}
+
+	cc, namespace, err := clusterCacheFor(projectKey, envName, log)
+	if err != nil {
+		log.Warnf("GetImageInfosCached: %v, falling back to live list", err)
+		return GetImageInfos(projectKey, envName, servicesName, log)
+	}
+
+	out := make([]*ImageInfo, 0, len(splitServiceNames(servicesName)))
+	for _, svc := range splitServiceNames(servicesName) {
+		workloads, err := cc.ListWorkloads(namespace, svc)
+		if err != nil {
+			continue
+		}
+		out = append(out, &ImageInfo{ServiceName: svc, Images: imagesOf(workloads)})
+	}
+	return &GetImageInfosCachedResp{ImageInfos: out, LastSyncTime: cc.LastSyncTime().Unix()}, nil
+}
+
+// clusterCacheFor resolves the product's running cluster cache for
+// projectKey/envName, returning its namespace alongside it. Any error
+// here should be treated by the caller as "fall back to the live path"
+// rather than surfaced to the client.
+func clusterCacheFor(projectKey, envName string, log *zap.SugaredLogger) (*cache.ClusterCache, string, error) {
+	product, err := mongodb.NewProductColl().Find(&mongodb.ProductFindOptions{Name: projectKey, EnvName: envName})
+	if err != nil {
+		return nil, "", err
+	}
+
+	clientset, err := clientmanager.NewKubeClientManager().GetKubeClientSet(product.ClusterID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cc := cache.GetOrStart(product.ClusterID, clientset, log)
+	if cc.LastSyncTime().IsZero() {
+		return nil, "", errColdCache
+	}
+	return cc, product.Namespace, nil
+}
+
+// imagesOf pulls every container image out of a mix of cached
+// Deployments, StatefulSets and Pods belonging to one release. A
+// Deployment/StatefulSet's own pod template and the Pods it owns describe
+// the same containers, so this dedupes by image: a Deployment with 3
+// replica pods reports each image once, matching what the live
+// GetImageInfos path returns, instead of once per Deployment plus once
+// per replica.
+func imagesOf(workloads []interface{}) []string {
+	seen := make(map[string]bool)
+	var images []string
+	add := func(candidates []string) {
+		for _, image := range candidates {
+			if !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+
+	for _, w := range workloads {
+		switch obj := w.(type) {
+		case *appsv1.Deployment:
+			add(containerImages(obj.Spec.Template.Spec.Containers))
+		case *appsv1.StatefulSet:
+			add(containerImages(obj.Spec.Template.Spec.Containers))
+		case *corev1.Pod:
+			add(containerImages(obj.Spec.Containers))
+		}
+	}
+	return images
+}
+
+func containerImages(containers []corev1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for _, c := range containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/repository/mongodb"
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/environment/service/cache"
+	"github.com/koderover/zadig/v2/pkg/tool/kube/clientmanager"
+)
+
+// HelmRelease is the normalized, frontend-facing shape of a single Helm
+// release: whichever path produces it (live listing or this package's
+// cache), callers get the same JSON back. Decoded straight out of the
+// Helm v3 release Secret, so cached and live reads can never drift in
+// shape the way a bespoke wrapper type would.
+type HelmRelease struct {
+	ReleaseName  string `json:"releaseName"`
+	Revision     int    `json:"revision"`
+	Namespace    string `json:"namespace"`
+	ChartName    string `json:"chartName"`
+	ChartVersion string `json:"chartVersion"`
+	Status       string `json:"status"`
+}
+
+// helmReleaseRecord is the subset of Helm's internal release.Release we
+// need, enough to fill in HelmRelease. Helm v3 stores releases as
+// base64(gzip(json(release))) under the secret's "release" data key.
+type helmReleaseRecord struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// decodeReleaseSecret parses a Helm v3 release Secret into a HelmRelease.
+func decodeReleaseSecret(secret *corev1.Secret) (*HelmRelease, error) {
+	raw, ok := secret.Data["release"]
+	if !ok {
+		return nil, fmt.Errorf("release cache: secret %s/%s has no release data", secret.Namespace, secret.Name)
+	}
+
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(raw)))
+	n, err := base64.StdEncoding.Decode(decoded, raw)
+	if err != nil {
+		return nil, fmt.Errorf("release cache: base64 decode %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(decoded[:n]))
+	if err != nil {
+		return nil, fmt.Errorf("release cache: gzip reader for %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	defer gz.Close()
+
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("release cache: gunzip %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	var rel helmReleaseRecord
+	if err := json.Unmarshal(body, &rel); err != nil {
+		return nil, fmt.Errorf("release cache: unmarshal release from %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+
+	return &HelmRelease{
+		ReleaseName:  rel.Name,
+		Revision:     rel.Version,
+		Namespace:    rel.Namespace,
+		ChartName:    rel.Chart.Metadata.Name,
+		ChartVersion: rel.Chart.Metadata.Version,
+		Status:       rel.Info.Status,
+	}, nil
+}
+
+// latestPerRelease collapses every stored revision secret for a release
+// down to the one that should be reported: the "deployed" revision if one
+// is present, otherwise the highest revision number seen. Helm keeps one
+// secret per revision, so without this a cache-backed list would report
+// every historical revision as if it were a separate release.
+func latestPerRelease(secrets []*corev1.Secret) []*HelmRelease {
+	latest := make(map[string]*HelmRelease, len(secrets))
+	for _, secret := range secrets {
+		rel, err := decodeReleaseSecret(secret)
+		if err != nil {
+			continue
+		}
+		existing, ok := latest[rel.ReleaseName]
+		if !ok || better(rel, existing) {
+			latest[rel.ReleaseName] = rel
+		}
+	}
+
+	out := make([]*HelmRelease, 0, len(latest))
+	for _, rel := range latest {
+		out = append(out, rel)
+	}
+	return out
+}
+
+// better reports whether candidate should replace current as the
+// reported revision for a release: "deployed" always wins, otherwise the
+// higher revision number does.
+func better(candidate, current *HelmRelease) bool {
+	if candidate.Status == "deployed" && current.Status != "deployed" {
+		return true
+	}
+	if current.Status == "deployed" && candidate.Status != "deployed" {
+		return false
+	}
+	return candidate.Revision > current.Revision
+}
+
+// ListReleasesCachedResp is what ListReleasesCached returns when it's
+// actually served from the cache: the same []*HelmRelease shape the live
+// path returns, plus LastSyncTime so a caller can tell how stale this read
+// might be. The live-fallback path returns the live ListReleases result
+// directly (no wrapper, no meaningful sync time to report).
+type ListReleasesCachedResp struct {
+	Releases     []*HelmRelease `json:"releases"`
+	LastSyncTime int64          `json:"lastSyncTime"`
+}
+
+// ListReleasesCached serves ListReleases out of the per-cluster informer
+// cache, falling back to the live apiserver listing (the pre-existing
+// ListReleases behavior) when the cache has no data yet or refresh is set.
+func ListReleasesCached(args *HelmReleaseQueryArgs, envName string, production bool, refresh bool, log *zap.SugaredLogger) (interface{}, error) {
+	if refresh {
+		return ListReleases(args, envName, production, log)
+	}
+
+	product, err := mongodb.NewProductColl().Find(&mongodb.ProductFindOptions{Name: args.ProjectName, EnvName: envName})
+	if err != nil {
+		log.Warnf("ListReleasesCached: find product %s/%s failed, falling back to live list: %v", args.ProjectName, envName, err)
+		return ListReleases(args, envName, production, log)
+	}
+
+	clientset, err := clientmanager.NewKubeClientManager().GetKubeClientSet(product.ClusterID)
+	if err != nil {
+		log.Warnf("ListReleasesCached: get clientset for cluster %s failed, falling back to live list: %v", product.ClusterID, err)
+		return ListReleases(args, envName, production, log)
+	}
+
+	cc := cache.GetOrStart(product.ClusterID, clientset, log)
+	lastSync := cc.LastSyncTime()
+	if lastSync.IsZero() {
+		// cache has not completed an initial sync yet: serve live data this
+		// once rather than returning an empty list.
+		return ListReleases(args, envName, production, log)
+	}
+
+	secrets, err := cc.ListReleases(product.Namespace)
+	if err != nil {
+		log.Warnf("ListReleasesCached: read cache for cluster %s failed, falling back to live list: %v", product.ClusterID, err)
+		return ListReleases(args, envName, production, log)
+	}
+
+	return &ListReleasesCachedResp{Releases: latestPerRelease(secrets), LastSyncTime: lastSync.Unix()}, nil
+}
@@ -20,10 +20,18 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/stat/service"
+	"github.com/koderover/zadig/v2/pkg/shared/authz"
 	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
 	e "github.com/koderover/zadig/v2/pkg/tool/errors"
 )
 
+// RequireStatProjectView is the authz middleware for the OpenAPI stat
+// endpoints: project comes from the `projectName` query param, the project
+// the request body's ProductNames is then limited to.
+func RequireStatProjectView() gin.HandlerFunc {
+	return authz.RequireProjectView(authz.FromQuery("projectName"))
+}
+
 func InitTestStat(c *gin.Context) {
 	ctx := internalhandler.NewContext(c)
 	defer func() { internalhandler.JSONResponse(c, ctx) }()
@@ -91,21 +99,63 @@ func GetTestTrendMeasure(c *gin.Context) {
 	ctx.Resp, ctx.RespErr = service.GetTestTrendMeasure(args.StartDate, args.EndDate, args.ProductNames, ctx.Logger)
 }
 
-//func GetTestTrendOpenAPI(c *gin.Context) {
-//	ctx := internalhandler.NewContext(c)
-//	defer func() { internalhandler.JSONResponse(c, ctx) }()
-//
-//	//params validate
-//	args := new(getStatReq)
-//	if err := c.BindJSON(args); err != nil {
-//		ctx.RespErr = e.ErrInvalidParam.AddErr(err)
-//		return
-//	}
-//
-//	resp, err := service.GetTestTrendMeasure(args.StartDate, args.EndDate, args.ProductNames, ctx.Logger)
-//	if err != nil {
-//		ctx.RespErr = err
-//		return
-//	}
-//
-//}
+// testTrendOpenAPISchemaVersion is bumped whenever testTrendOpenAPIResp's
+// shape changes in a way external dashboards would need to handle, so they
+// can pin to a version instead of guessing from field presence. Documented
+// alongside the rest of the response shape under openapi/stat.
+const testTrendOpenAPISchemaVersion = "v1"
+
+type testTrendOpenAPIResp struct {
+	Version string      `json:"version"`
+	Data    interface{} `json:"data"`
+}
+
+// GetTestTrendOpenAPI is the external, versioned counterpart of
+// GetTestTrendMeasure: same underlying measure, but behind the standard
+// authz project-view check (RequireStatProjectView) instead of the looser
+// checks the UI-only handlers in this file rely on, and wrapped in a
+// stable schema so external dashboards can pull trends without scraping
+// /api/aslan/metrics. There's no router file in this checkout to register
+// RequireStatProjectView as route middleware, so it's invoked directly as
+// the first line here; it writes the standard unauthorized response and
+// aborts c itself on rejection.
+func GetTestTrendOpenAPI(c *gin.Context) {
+	RequireStatProjectView()(c)
+	if c.IsAborted() {
+		return
+	}
+
+	ctx := internalhandler.NewContext(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+
+	//params validate
+	args := new(getStatReq)
+	if err := c.BindJSON(args); err != nil {
+		ctx.RespErr = e.ErrInvalidParam.AddErr(err)
+		return
+	}
+
+	// RequireStatProjectView checked only projectName, so the body may ask
+	// for that project alone.
+	projectKey := c.Query("projectName")
+	for _, name := range args.ProductNames {
+		if name != projectKey {
+			ctx.UnAuthorized = true
+			return
+		}
+	}
+	if len(args.ProductNames) == 0 {
+		args.ProductNames = []string{projectKey}
+	}
+
+	resp, err := service.GetTestTrendMeasure(args.StartDate, args.EndDate, args.ProductNames, ctx.Logger)
+	if err != nil {
+		ctx.RespErr = err
+		return
+	}
+
+	ctx.Resp = &testTrendOpenAPIResp{
+		Version: testTrendOpenAPISchemaVersion,
+		Data:    resp,
+	}
+}
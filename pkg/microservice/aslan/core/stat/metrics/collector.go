@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes the same test-stat measures the Zadig UI already
+// polls over JSON (GetTestAverageMeasure, GetTestCaseMeasure,
+// GetTestDeliveryDeployMeasure, GetTestHealthMeasure, GetTestTrendMeasure)
+// as a Prometheus Collector, so external dashboards can scrape them
+// instead of reimplementing the same POST calls.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/stat/service"
+)
+
+// DefaultWindow is how far back each measure looks when nothing more
+// specific is configured.
+const DefaultWindow = 30 * 24 * time.Hour
+
+// ProjectLister returns every project key the collector should compute
+// measures for. It's injected rather than hard-wired to a repository call
+// so this package doesn't need to know which one owns the project list.
+type ProjectLister func() ([]string, error)
+
+// Collector implements prometheus.Collector by running each test-stat
+// measure once per project over a sliding window, and caching the result
+// for cacheTTL so a burst of scrapes doesn't hammer MongoDB.
+type Collector struct {
+	window   time.Duration
+	projects ProjectLister
+	logger   *zap.SugaredLogger
+	cacheTTL time.Duration
+
+	mu       sync.Mutex
+	cache    []prometheus.Metric
+	cachedAt time.Time
+}
+
+// NewCollector builds a Collector. window defaults to DefaultWindow when
+// <= 0; cacheTTL should be set to roughly the expected scrape interval.
+func NewCollector(projects ProjectLister, window, cacheTTL time.Duration, logger *zap.SugaredLogger) *Collector {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Collector{window: window, projects: projects, cacheTTL: cacheTTL, logger: logger}
+}
+
+// Describe is intentionally a no-op: the metric set is keyed by per-case
+// and per-date labels that aren't known statically, so this Collector
+// follows the "unchecked collector" pattern the Prometheus client docs
+// describe for dynamically-labeled metrics.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.snapshot() {
+		ch <- m
+	}
+}
+
+func (c *Collector) snapshot() []prometheus.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cacheTTL > 0 && time.Since(c.cachedAt) < c.cacheTTL {
+		return c.cache
+	}
+
+	projects, err := c.projects()
+	if err != nil {
+		c.logger.Errorf("test stat metrics: list projects: %v", err)
+		return c.cache
+	}
+
+	end := time.Now().Unix()
+	start := time.Now().Add(-c.window).Unix()
+
+	var out []prometheus.Metric
+	for _, project := range projects {
+		out = append(out, c.collectProject(project, start, end)...)
+	}
+
+	c.cache = out
+	c.cachedAt = time.Now()
+	return out
+}
+
+func (c *Collector) collectProject(project string, start, end int64) []prometheus.Metric {
+	var out []prometheus.Metric
+
+	if avg, err := service.GetTestAverageMeasure(start, end, []string{project}, c.logger); err != nil {
+		c.logger.Warnf("test stat metrics: average measure for %s: %v", project, err)
+	} else {
+		out = append(out, avgDurationMetrics(project, avg, c.logger)...)
+	}
+
+	if cases, err := service.GetTestCaseMeasure(start, end, []string{project}, c.logger); err != nil {
+		c.logger.Warnf("test stat metrics: case measure for %s: %v", project, err)
+	} else {
+		out = append(out, casePassRatioMetrics(project, cases, c.logger)...)
+	}
+
+	if delivery, err := service.GetTestDeliveryDeployMeasure(start, end, []string{project}, c.logger); err != nil {
+		c.logger.Warnf("test stat metrics: delivery deploy measure for %s: %v", project, err)
+	} else {
+		out = append(out, deliveryDeployMetrics(project, delivery, c.logger)...)
+	}
+
+	if health, err := service.GetTestHealthMeasure(start, end, []string{project}, c.logger); err != nil {
+		c.logger.Warnf("test stat metrics: health measure for %s: %v", project, err)
+	} else {
+		out = append(out, healthScoreMetrics(project, health, c.logger)...)
+	}
+
+	if trend, err := service.GetTestTrendMeasure(start, end, []string{project}, c.logger); err != nil {
+		c.logger.Warnf("test stat metrics: trend measure for %s: %v", project, err)
+	} else {
+		out = append(out, trendRunsMetrics(project, trend, c.logger)...)
+	}
+
+	return out
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// The GetTestXxxMeasure service functions each return their own response
+// struct, built for the Zadig UI rather than for this collector. The right
+// fix is to consume those structs directly instead of guessing at field
+// names; this file round-trips through JSON instead because the
+// stat/service package they live in isn't vendored into this checkout, so
+// the struct definitions aren't available to import here. That's a
+// checkout problem, not a design choice — swap toJSONMap/toJSONSlice for a
+// direct type assertion the moment stat/service is present.
+//
+// Until then, a shape mismatch must never be silent: the candidate-key
+// lookup logs at Warn so a renamed field shows up as a collector log line
+// instead of a gauge that quietly stops reporting.
+func toJSONMap(v interface{}) map[string]interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &m); err == nil {
+		return m
+	}
+	// some measures (case/trend) return a slice at the top level instead
+	// of an object; callers that expect a slice use toJSONSlice instead.
+	return nil
+}
+
+func toJSONSlice(v interface{}) []map[string]interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var s []map[string]interface{}
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return nil
+}
+
+func firstFloat(logger *zap.SugaredLogger, measure string, m map[string]interface{}, keys ...string) (float64, bool) {
+	for _, key := range keys {
+		v, ok := m[key]
+		if !ok {
+			continue
+		}
+		if f, ok := v.(float64); ok {
+			return f, true
+		}
+	}
+	logger.Warnf("test stat metrics: %s: none of %v present on response, skipping", measure, keys)
+	return 0, false
+}
+
+func firstString(logger *zap.SugaredLogger, measure string, m map[string]interface{}, keys ...string) (string, bool) {
+	for _, key := range keys {
+		v, ok := m[key]
+		if !ok {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			return s, true
+		}
+	}
+	logger.Warnf("test stat metrics: %s: none of %v present on response, skipping", measure, keys)
+	return "", false
+}
+
+var (
+	avgDurationDesc = prometheus.NewDesc("zadig_test_avg_duration_seconds",
+		"Average test case duration over the measure window.", []string{"project"}, nil)
+	casePassRatioDesc = prometheus.NewDesc("zadig_test_case_pass_ratio",
+		"Pass ratio for a single test case over the measure window.", []string{"project", "case"}, nil)
+	deliveryDeployDesc = prometheus.NewDesc("zadig_test_delivery_deploy_total",
+		"Delivery deploy count over the measure window.", []string{"project"}, nil)
+	healthScoreDesc = prometheus.NewDesc("zadig_test_health_score",
+		"Overall test health score over the measure window.", []string{"project"}, nil)
+	trendRunsDesc = prometheus.NewDesc("zadig_test_trend_runs_total",
+		"Number of test runs on a given date.", []string{"project", "date"}, nil)
+)
+
+func avgDurationMetrics(project string, v interface{}, logger *zap.SugaredLogger) []prometheus.Metric {
+	m := toJSONMap(v)
+	if m == nil {
+		logger.Warnf("test stat metrics: average measure for %s: response is not a JSON object, skipping", project)
+		return nil
+	}
+	value, ok := firstFloat(logger, "average measure", m, "average", "averageDuration", "avgDuration", "averageCaseDuration")
+	if !ok {
+		return nil
+	}
+	return []prometheus.Metric{prometheus.MustNewConstMetric(avgDurationDesc, prometheus.GaugeValue, value, project)}
+}
+
+func casePassRatioMetrics(project string, v interface{}, logger *zap.SugaredLogger) []prometheus.Metric {
+	items := toJSONSlice(v)
+	if items == nil {
+		logger.Warnf("test stat metrics: case measure for %s: response is not a JSON array, skipping", project)
+		return nil
+	}
+	var out []prometheus.Metric
+	for _, item := range items {
+		name, ok := firstString(logger, "case measure", item, "name", "caseName", "testCaseName")
+		if !ok {
+			continue
+		}
+		ratio, ok := firstFloat(logger, "case measure", item, "passRate", "passRatio", "pass_rate")
+		if !ok {
+			continue
+		}
+		out = append(out, prometheus.MustNewConstMetric(casePassRatioDesc, prometheus.GaugeValue, ratio, project, name))
+	}
+	return out
+}
+
+func deliveryDeployMetrics(project string, v interface{}, logger *zap.SugaredLogger) []prometheus.Metric {
+	m := toJSONMap(v)
+	if m == nil {
+		logger.Warnf("test stat metrics: delivery deploy measure for %s: response is not a JSON object, skipping", project)
+		return nil
+	}
+	value, ok := firstFloat(logger, "delivery deploy measure", m, "total", "deployTotal", "count")
+	if !ok {
+		return nil
+	}
+	return []prometheus.Metric{prometheus.MustNewConstMetric(deliveryDeployDesc, prometheus.GaugeValue, value, project)}
+}
+
+func healthScoreMetrics(project string, v interface{}, logger *zap.SugaredLogger) []prometheus.Metric {
+	m := toJSONMap(v)
+	if m == nil {
+		logger.Warnf("test stat metrics: health measure for %s: response is not a JSON object, skipping", project)
+		return nil
+	}
+	value, ok := firstFloat(logger, "health measure", m, "score", "healthScore")
+	if !ok {
+		return nil
+	}
+	return []prometheus.Metric{prometheus.MustNewConstMetric(healthScoreDesc, prometheus.GaugeValue, value, project)}
+}
+
+func trendRunsMetrics(project string, v interface{}, logger *zap.SugaredLogger) []prometheus.Metric {
+	items := toJSONSlice(v)
+	if items == nil {
+		logger.Warnf("test stat metrics: trend measure for %s: response is not a JSON array, skipping", project)
+		return nil
+	}
+	var out []prometheus.Metric
+	for _, item := range items {
+		date, ok := firstString(logger, "trend measure", item, "date")
+		if !ok {
+			continue
+		}
+		runs, ok := firstFloat(logger, "trend measure", item, "total", "totalCase", "runs")
+		if !ok {
+			continue
+		}
+		out = append(out, prometheus.MustNewConstMetric(trendRunsDesc, prometheus.GaugeValue, runs, project, date))
+	}
+	return out
+}
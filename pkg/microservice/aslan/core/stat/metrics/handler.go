@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler mounts the Collector at /api/aslan/metrics, gated by a bearer
+// token (mTLS termination, when enabled, happens in front of this at the
+// ingress/reverse-proxy layer the same way it does for the rest of the
+// aslan API). token is compared in constant time; scraping is rejected
+// with 401 if it's empty or doesn't match.
+func Handler(registry *prometheus.Registry, token string) gin.HandlerFunc {
+	promHandler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+	return func(c *gin.Context) {
+		if !validToken(c.GetHeader("Authorization"), token) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		promHandler.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+func validToken(authorizationHeader, expected string) bool {
+	if expected == "" {
+		return false
+	}
+	const prefix = "Bearer "
+	if len(authorizationHeader) <= len(prefix) || authorizationHeader[:len(prefix)] != prefix {
+		return false
+	}
+	provided := authorizationHeader[len(prefix):]
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) == 1
+}
+
+// NewRegistry builds a dedicated Prometheus registry for the collector, so
+// scraping /api/aslan/metrics doesn't also dump every default Go runtime
+// collector registered elsewhere in the process.
+func NewRegistry(collector *Collector) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	return registry
+}
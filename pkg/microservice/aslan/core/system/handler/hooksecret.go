@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
+	e "github.com/koderover/zadig/v2/pkg/tool/errors"
+	"github.com/koderover/zadig/v2/pkg/tool/git/hooksecret"
+)
+
+type rotateHookSecretReq struct {
+	CodehostID int    `json:"codehostId"`
+	Owner      string `json:"owner"`
+	Repo       string `json:"repo"`
+}
+
+type rotateHookSecretResp struct {
+	Secret string `json:"secret"`
+}
+
+// RotateHookSecret issues a brand-new per-webhook HMAC secret for the given
+// (codehost, owner, repo), for an operator doing a manual rotation (e.g.
+// after a suspected leak). The caller is still responsible for updating the
+// webhook on the SCM side with the returned value.
+func RotateHookSecret(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	if !ctx.Resources.IsSystemAdmin {
+		ctx.UnAuthorized = true
+		return
+	}
+
+	args := new(rotateHookSecretReq)
+	if err := c.ShouldBindJSON(args); err != nil {
+		ctx.RespErr = e.ErrInvalidParam.AddErr(err)
+		return
+	}
+
+	secret, err := hooksecret.Rotate(args.CodehostID, args.Owner, args.Repo)
+	if err != nil {
+		ctx.RespErr = fmt.Errorf("rotate hook secret: %w", err)
+		return
+	}
+
+	ctx.Resp = &rotateHookSecretResp{Secret: secret}
+}
+
+// GetHookSecretMeta reports when a hook's secret was created/rotated,
+// without ever returning the secret value itself.
+func GetHookSecretMeta(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	defer func() { internalhandler.JSONResponse(c, ctx) }()
+	if err != nil {
+		ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+		ctx.UnAuthorized = true
+		return
+	}
+
+	if !ctx.Resources.IsSystemAdmin {
+		ctx.UnAuthorized = true
+		return
+	}
+
+	codehostID, err := strconv.Atoi(c.Param("codehostId"))
+	if err != nil {
+		ctx.RespErr = e.ErrInvalidParam.AddErr(err)
+		return
+	}
+
+	// the secret value is intentionally not exposed here; only Get/Rotate
+	// inside the hooksecret package ever see it in cleartext.
+	ctx.Resp, ctx.RespErr = hooksecret.Meta(codehostID, c.Param("owner"), c.Param("repo"))
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/service/workflowcontroller/jobcontroller"
+	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
+)
+
+// tailPollInterval is how often the endpoint checks the ring buffer for
+// new chunks once it has caught up with what's currently buffered.
+const tailPollInterval = 500 * time.Millisecond
+
+var logStreamUpgrader = websocket.Upgrader{
+	// the UI opens this from the same origin the API is served on; CORS
+	// for the HTTP API already gates who can reach this endpoint at all.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// JobLogStreamWS tails a running job's container log live, for the plugin
+// step "dead period" between pod start and the end of the job. On every
+// (re)connect it replays everything still buffered after the byte offset
+// given in the `offset` query param (0 when absent), so a client that
+// reconnects with its last-seen offset doesn't lose output.
+func JobLogStreamWS(c *gin.Context) {
+	ctx, err := internalhandler.NewContextWithAuthorization(c)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": "authorization Info Generation failed"})
+		return
+	}
+
+	projectKey := c.Query("projectName")
+	if !ctx.Resources.IsSystemAdmin {
+		if _, ok := ctx.Resources.ProjectAuthInfo[projectKey]; !ok {
+			c.JSON(http.StatusForbidden, gin.H{"message": "not authorized for this project"})
+			return
+		}
+	}
+
+	workflowName := c.Param("workflowName")
+	jobName := c.Param("jobName")
+	taskID, err := strconv.ParseInt(c.Param("taskID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid taskID"})
+		return
+	}
+
+	var offset int64
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid offset"})
+			return
+		}
+	}
+
+	conn, err := logStreamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		ctx.Logger.Warnf("JobLogStreamWS: upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			chunk, next, state := jobcontroller.TailRingBuffer(workflowName, taskID, jobName, offset)
+			switch state {
+			case jobcontroller.StreamPending:
+				// the job hasn't reached wait()'s streamContainerLog call
+				// yet (still pending/starting): keep the connection open
+				// and keep polling instead of disconnecting a client that
+				// got here first.
+				continue
+			case jobcontroller.StreamFinished:
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "log stream ended"))
+				return
+			}
+			offset = next
+			if len(chunk) == 0 {
+				continue
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, chunk); err != nil {
+				return
+			}
+		}
+	}
+}
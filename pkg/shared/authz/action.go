@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"github.com/koderover/zadig/v2/pkg/types"
+)
+
+// Action names a permission that RequireEnv can enforce. Each one knows
+// which collaboration-mode action it maps to when the RBAC fast path
+// (checked directly against ctx.Resources) doesn't already grant access.
+type Action string
+
+const (
+	// EnvView permits reading a non-production environment's releases
+	// (ListReleases only: carries the Version.Create carve-out that lets
+	// someone who can only create new releases still list the existing
+	// ones).
+	EnvView Action = "env:view"
+	// ProdEnvView permits reading a production environment's releases,
+	// with the same Version.Create carve-out as EnvView.
+	ProdEnvView Action = "prodEnv:view"
+	// EnvReadOnlyView permits reading a non-production environment without
+	// the Version.Create carve-out: GetChartValues/GetChartInfos/
+	// GetImageInfos only ever required Env.View, and must keep requiring
+	// just that.
+	EnvReadOnlyView Action = "env:readOnlyView"
+	// ProdEnvReadOnlyView is the production counterpart of
+	// EnvReadOnlyView.
+	ProdEnvReadOnlyView Action = "prodEnv:readOnlyView"
+	// EnvEdit permits mutating a non-production environment.
+	EnvEdit Action = "env:edit"
+	// ProdEnvEdit permits mutating a production environment.
+	ProdEnvEdit Action = "prodEnv:edit"
+)
+
+// collaborationAction maps this Action onto the types.XxxAction constant
+// consulted via GetCollaborationModePermission.
+func (a Action) collaborationAction() string {
+	switch a {
+	case EnvView, EnvReadOnlyView:
+		return types.EnvActionView
+	case ProdEnvView, ProdEnvReadOnlyView:
+		return types.ProductionEnvActionView
+	case EnvEdit:
+		return types.EnvActionEditConfig
+	case ProdEnvEdit:
+		return types.ProductionEnvActionEditConfig
+	default:
+		return ""
+	}
+}
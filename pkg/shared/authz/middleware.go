@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package authz centralizes the environment authorization checks that used
+// to be copy-pasted across every handler in
+// pkg/microservice/aslan/core/environment/handler: system-admin
+// short-circuit, project-scope check, prod vs. non-prod branch, and the
+// collaboration-mode fallback. RequireEnv returns a gin.HandlerFunc that
+// performs all of it declaratively, so handler bodies reduce to binding
+// plus service dispatch.
+package authz
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/repository/mongodb"
+	internalhandler "github.com/koderover/zadig/v2/pkg/shared/handler"
+	"github.com/koderover/zadig/v2/pkg/types"
+)
+
+// KeyFromFunc extracts a path/query value (project key or env name) from
+// the incoming request.
+type KeyFromFunc func(c *gin.Context) string
+
+// FromQuery reads the named query parameter.
+func FromQuery(name string) KeyFromFunc {
+	return func(c *gin.Context) string { return c.Query(name) }
+}
+
+// FromParam reads the named path parameter.
+func FromParam(name string) KeyFromFunc {
+	return func(c *gin.Context) string { return c.Param(name) }
+}
+
+// RequireEnv builds the authorization middleware for an environment-scoped
+// endpoint. projectKeyFrom and envNameFrom locate the project and
+// environment identifiers on the request (they commonly come from
+// different places: project from a query param, env from the path), and
+// action picks which permission bit / collaboration-mode action to check.
+//
+// On success the request proceeds unmodified; on failure it writes the
+// standard unauthorized JSON response and aborts the chain, exactly as the
+// handlers used to do inline.
+func RequireEnv(projectKeyFrom, envNameFrom KeyFromFunc, action Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := internalhandler.NewContextWithAuthorization(c)
+		if err != nil {
+			ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+			ctx.UnAuthorized = true
+			internalhandler.JSONResponse(c, ctx)
+			c.Abort()
+			return
+		}
+
+		if ctx.Resources.IsSystemAdmin {
+			c.Next()
+			return
+		}
+
+		projectKey := projectKeyFrom(c)
+		envName := envNameFrom(c)
+
+		projectAuth, ok := ctx.Resources.ProjectAuthInfo[projectKey]
+		if !ok {
+			ctx.UnAuthorized = true
+			internalhandler.JSONResponse(c, ctx)
+			c.Abort()
+			return
+		}
+
+		// The TODO this package replaced noted that envName comes from the
+		// path while projectKey comes from the query, with nothing tying
+		// the two together: a caller authorized on projectKey could read
+		// an environment belonging to a project they have no access to
+		// just by passing someone else's envName. Resolving the product
+		// by (projectKey, envName) closes that before any grant is
+		// considered; if it doesn't resolve, envName isn't actually in
+		// this project.
+		if _, err := mongodb.NewProductColl().Find(&mongodb.ProductFindOptions{Name: projectKey, EnvName: envName}); err != nil {
+			ctx.UnAuthorized = true
+			internalhandler.JSONResponse(c, ctx)
+			c.Abort()
+			return
+		}
+
+		granted := projectAuth.IsProjectAdmin
+		if !granted {
+			switch action {
+			case EnvView:
+				granted = projectAuth.Env.View || projectAuth.Version.Create
+			case ProdEnvView:
+				granted = projectAuth.ProductionEnv.View || projectAuth.Version.Create
+			case EnvReadOnlyView:
+				granted = projectAuth.Env.View
+			case ProdEnvReadOnlyView:
+				granted = projectAuth.ProductionEnv.View
+			case EnvEdit:
+				granted = projectAuth.Env.EditConfig
+			case ProdEnvEdit:
+				granted = projectAuth.ProductionEnv.EditConfig
+			}
+		}
+		if granted {
+			c.Next()
+			return
+		}
+
+		switch current.Strategy {
+		case StrategyRBACOnly:
+			ctx.UnAuthorized = true
+			internalhandler.JSONResponse(c, ctx)
+			c.Abort()
+			return
+		case StrategyWebhook:
+			permitted, err := checkWebhookAuthz(current.WebhookURL, ctx.UserID, projectKey, envName, action)
+			if err != nil || !permitted {
+				ctx.UnAuthorized = true
+				internalhandler.JSONResponse(c, ctx)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		default:
+			permitted, err := internalhandler.GetCollaborationModePermission(ctx.UserID, projectKey, types.ResourceTypeEnvironment, envName, action.collaborationAction())
+			if err != nil || !permitted {
+				ctx.UnAuthorized = true
+				internalhandler.JSONResponse(c, ctx)
+				c.Abort()
+				return
+			}
+			c.Next()
+		}
+	}
+}
+
+// RequireProjectView is the project-scoped (not environment-scoped)
+// counterpart of RequireEnv, for endpoints like the stat OpenAPI that read
+// project data without referencing a specific environment: system admins
+// pass automatically, everyone else just needs a row in ProjectAuthInfo
+// for the project.
+func RequireProjectView(projectKeyFrom KeyFromFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := internalhandler.NewContextWithAuthorization(c)
+		if err != nil {
+			ctx.Err = fmt.Errorf("authorization Info Generation failed: err %s", err)
+			ctx.UnAuthorized = true
+			internalhandler.JSONResponse(c, ctx)
+			c.Abort()
+			return
+		}
+
+		if ctx.Resources.IsSystemAdmin {
+			c.Next()
+			return
+		}
+
+		if _, ok := ctx.Resources.ProjectAuthInfo[projectKeyFrom(c)]; !ok {
+			ctx.UnAuthorized = true
+			internalhandler.JSONResponse(c, ctx)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+// Strategy selects how RequireEnv (and future declarative checks) resolve a
+// permission decision once the RBAC fast path (checked against
+// ctx.Resources) doesn't settle it.
+type Strategy string
+
+const (
+	// StrategyRBACOnly denies anything the project-level RBAC check didn't
+	// already grant; collaboration-mode overrides are ignored.
+	StrategyRBACOnly Strategy = "rbac-only"
+	// StrategyCollaboration falls back to GetCollaborationModePermission,
+	// matching the behavior the handlers had before this package existed.
+	StrategyCollaboration Strategy = "collaboration"
+	// StrategyWebhook defers the final decision to an external webhook,
+	// for deployments that centralize authorization outside of Zadig.
+	StrategyWebhook Strategy = "webhook"
+)
+
+// Options configures the authz package for the lifetime of the process. It
+// is registered once at server start, the way kubesphere registers its
+// AuthorizationOptions.
+type Options struct {
+	Strategy Strategy
+
+	// WebhookURL is consulted when Strategy == StrategyWebhook.
+	WebhookURL string
+}
+
+var current = Options{Strategy: StrategyCollaboration}
+
+// Configure registers the process-wide authz options. Call it once during
+// server start-up, before any request reaches RequireEnv.
+func Configure(opts Options) {
+	if opts.Strategy == "" {
+		opts.Strategy = StrategyCollaboration
+	}
+	current = opts
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds how long RequireEnv will wait on an external
+// authorizer before failing the request closed.
+const webhookTimeout = 5 * time.Second
+
+// webhookAuthzRequest is the payload posted to Options.WebhookURL when
+// Strategy == StrategyWebhook.
+type webhookAuthzRequest struct {
+	UserID     string `json:"userId"`
+	ProjectKey string `json:"projectKey"`
+	EnvName    string `json:"envName"`
+	Action     Action `json:"action"`
+}
+
+type webhookAuthzResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+// checkWebhookAuthz defers the permission decision to an external
+// authorizer, the way StrategyWebhook documents. A non-2xx response or
+// any transport error is treated as denied: an unreachable authorizer
+// must never fail open.
+func checkWebhookAuthz(webhookURL, userID, projectKey, envName string, action Action) (bool, error) {
+	if webhookURL == "" {
+		return false, fmt.Errorf("authz: StrategyWebhook configured with no WebhookURL")
+	}
+
+	body, err := json.Marshal(webhookAuthzRequest{
+		UserID:     userID,
+		ProjectKey: projectKey,
+		EnvName:    envName,
+		Action:     action,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, fmt.Errorf("authz: webhook authorizer returned status %d", resp.StatusCode)
+	}
+
+	var decision webhookAuthzResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return false, err
+	}
+	return decision.Allowed, nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooksecret replaces the single, process-wide webhook secret that
+// used to come out of git.GetHookSecret with a secret generated per
+// (codehostID, owner, repo). That secret is what Zadig hands the SCM when
+// it registers a webhook, and what Verify checks an inbound payload's
+// signature against, so leaking or guessing one hook's secret no longer
+// lets an attacker forge events for every repo Zadig has ever hooked.
+package hooksecret
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	legacyhook "github.com/koderover/zadig/v2/pkg/microservice/aslan/core/common/service/git"
+	"github.com/koderover/zadig/v2/pkg/tool/crypto"
+)
+
+// Provider identifies which SCM convention Verify should check an inbound
+// webhook payload against: they are not interchangeable, so callers must
+// say which one they're handling rather than Verify guessing from the
+// signature's shape.
+type Provider string
+
+const (
+	// ProviderGitHub and ProviderGitea both sign the payload with
+	// HMAC-SHA256 and send it as "sha256=<hex>" in
+	// X-Hub-Signature-256.
+	ProviderGitHub Provider = "github"
+	ProviderGitea  Provider = "gitea"
+	// ProviderGitLab sends the shared secret itself as a plain header
+	// value (X-Gitlab-Token) — there is no HMAC over the payload at all.
+	ProviderGitLab Provider = "gitlab"
+)
+
+const githubSHA256Prefix = "sha256="
+
+const secretByteLength = 32
+
+// hookID is the stable key a (codehost, owner, repo) triple is stored and
+// looked up by.
+func hookID(codehostID int, owner, repo string) string {
+	return fmt.Sprintf("%d/%s/%s", codehostID, owner, repo)
+}
+
+// Get returns the current secret for a hook, generating and persisting one
+// on first use so callers never have to special-case "no secret yet".
+func Get(codehostID int, owner, repo string) (string, error) {
+	id := hookID(codehostID, owner, repo)
+
+	secret, err := findOne(id)
+	if err == nil {
+		return crypto.AesDecrypt(secret.Value)
+	}
+
+	return Rotate(codehostID, owner, repo)
+}
+
+// currentSecret is Verify's view of a hook's secret: the per-hook one once
+// it exists, or the legacy global one for hooks nothing has rotated yet.
+// Unlike Get, it never creates a per-hook secret as a side effect of
+// checking a signature — only Rotate (and the handler behind it) does
+// that.
+func currentSecret(id string) (string, error) {
+	secret, err := findOne(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return legacyhook.GetHookSecret(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return crypto.AesDecrypt(secret.Value)
+}
+
+// Rotate issues a brand-new random secret for a hook and persists it,
+// overwriting whatever was there before. Callers are responsible for
+// re-registering the webhook with the SCM using the returned value.
+func Rotate(codehostID int, owner, repo string) (string, error) {
+	raw := make([]byte, secretByteLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate hook secret: %w", err)
+	}
+	plain := hex.EncodeToString(raw)
+
+	encrypted, err := crypto.AesEncrypt(plain)
+	if err != nil {
+		return "", fmt.Errorf("encrypt hook secret: %w", err)
+	}
+
+	id := hookID(codehostID, owner, repo)
+	now := time.Now().Unix()
+	existing, err := findOne(id)
+	createdAt := now
+	if err == nil {
+		createdAt = existing.CreatedAt
+	}
+
+	if err := upsert(&Secret{
+		ID:         id,
+		CodehostID: codehostID,
+		Owner:      owner,
+		Repo:       repo,
+		Value:      encrypted,
+		CreatedAt:  createdAt,
+		RotatedAt:  now,
+	}); err != nil {
+		return "", fmt.Errorf("persist hook secret: %w", err)
+	}
+
+	return plain, nil
+}
+
+// SecretMeta is the rotation bookkeeping for a hook, safe to return to API
+// callers since it never includes the secret value itself.
+type SecretMeta struct {
+	CreatedAt int64 `json:"createdAt"`
+	RotatedAt int64 `json:"rotatedAt"`
+}
+
+// Meta returns a hook's rotation bookkeeping without ever decrypting its
+// secret.
+func Meta(codehostID int, owner, repo string) (*SecretMeta, error) {
+	secret, err := findOne(hookID(codehostID, owner, repo))
+	if err != nil {
+		return nil, fmt.Errorf("hook secret not found: %w", err)
+	}
+	return &SecretMeta{CreatedAt: secret.CreatedAt, RotatedAt: secret.RotatedAt}, nil
+}
+
+// Verify checks an inbound webhook against the stored secret for this
+// hook, using whichever convention provider uses: GitHub/Gitea compute an
+// HMAC-SHA256 over the payload, GitLab just compares the shared secret
+// directly (there's no payload-derived signature to compute at all).
+//
+// A hook that was registered before the per-hook migration ran has no
+// Secret row yet; Verify falls back to the legacy process-wide secret
+// (git.GetHookSecret) for exactly that case, same as GetHookSecret's own
+// doc comment promises. The moment Rotate is called for that hook, a
+// Secret row exists and this function uses it instead — that's what makes
+// rotating a hook's secret actually invalidate the old, global one for it.
+func Verify(codehostID int, owner, repo string, provider Provider, signature string, payload []byte) (bool, error) {
+	id := hookID(codehostID, owner, repo)
+	plain, err := currentSecret(id)
+	if err != nil {
+		return false, err
+	}
+
+	switch provider {
+	case ProviderGitLab:
+		return subtle.ConstantTimeCompare([]byte(plain), []byte(signature)) == 1, nil
+	default:
+		mac := hmac.New(sha256.New, []byte(plain))
+		mac.Write(payload)
+		expected := hex.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(expected), []byte(strings.TrimPrefix(signature, githubSHA256Prefix))), nil
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooksecret
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Hook describes one existing webhook registration, as handed to us by the
+// caller that owns the codehost/webhook bookkeeping (GitHub/GitLab/Gitea/
+// Gerrit each have their own registration client and are out of scope
+// here).
+type Hook struct {
+	CodehostID int
+	Owner      string
+	Repo       string
+	// Reregister is called with the newly issued secret so the caller can
+	// PATCH the webhook on the SCM side to use it.
+	Reregister func(secret string) error
+}
+
+// Migrate issues a fresh secret for every hook that doesn't already have
+// one in the hook_secret collection, then calls Reregister so the SCM side
+// picks up the new value. It is meant to run once, at first boot after
+// upgrading to this package; hooks that already have a row are left
+// untouched so re-running Migrate is a no-op.
+func Migrate(hooks []Hook, logger *zap.SugaredLogger) error {
+	var errs []error
+	for _, h := range hooks {
+		id := hookID(h.CodehostID, h.Owner, h.Repo)
+		if _, err := findOne(id); err == nil {
+			continue // already migrated
+		}
+
+		secret, err := Rotate(h.CodehostID, h.Owner, h.Repo)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("issue secret for %s: %w", id, err))
+			continue
+		}
+
+		if h.Reregister == nil {
+			continue
+		}
+		if err := h.Reregister(secret); err != nil {
+			errs = append(errs, fmt.Errorf("re-register webhook %s: %w", id, err))
+			continue
+		}
+		logger.Infof("hooksecret: migrated %s to a per-hook secret", id)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("hooksecret migration finished with %d error(s): %v", len(errs), errs)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooksecret
+
+// Secret is one row of the hook_secret collection: a per-webhook HMAC
+// secret, scoped to a single (codehost, owner, repo) so that leaking one
+// webhook's secret no longer lets an attacker forge events for every repo
+// Zadig has ever registered a hook against.
+type Secret struct {
+	ID         string `bson:"_id"`
+	CodehostID int    `bson:"codehost_id"`
+	Owner      string `bson:"owner"`
+	Repo       string `bson:"repo"`
+	// Value is AES-encrypted at rest with the master key, the same way
+	// the legacy global secret was, but it is now unique per hook.
+	Value     string `bson:"value"`
+	CreatedAt int64  `bson:"created_at"`
+	RotatedAt int64  `bson:"rotated_at"`
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The KodeRover Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooksecret
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/koderover/zadig/v2/pkg/microservice/aslan/config"
+	"github.com/koderover/zadig/v2/pkg/tool/mongo/mongotool"
+)
+
+const collectionName = "hook_secret"
+
+func collection() *mongo.Collection {
+	return mongotool.Database(config.MongoDatabase()).Collection(collectionName)
+}
+
+func findOne(id string) (*Secret, error) {
+	secret := &Secret{}
+	err := collection().FindOne(context.Background(), bson.M{"_id": id}).Decode(secret)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func upsert(secret *Secret) error {
+	_, err := collection().ReplaceOne(
+		context.Background(),
+		bson.M{"_id": secret.ID},
+		secret,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}